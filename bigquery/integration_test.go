@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -125,6 +126,82 @@ func TestIntegration(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Load more rows from a local reader, without staging them in GCS.
+	csv := strings.NewReader("d,3\ne,4\n")
+	loadJob, err := table.LoadFromReader(ctx, csv, LoadConfig{
+		SourceFormat:     CSV,
+		Schema:           schema,
+		WriteDisposition: WriteAppend,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadJob.Status(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rows = append(rows,
+		&ValuesSaver{Schema: schema, Row: []Value{"d", 3}},
+		&ValuesSaver{Schema: schema, Row: []Value{"e", 4}})
+
+	// Round-trip data through GCS: extract the table to a Parquet file,
+	// then load it back into a second table.
+	const gcsURI = "gs://bigquery-integration-test/t1.parquet"
+	extractJob, err := table.ExtractorTo(GCSReference{
+		URIs:         []string{gcsURI},
+		SourceFormat: Parquet,
+	}).Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, err := extractJob.Wait(ctx); err != nil {
+		t.Fatal(err)
+	} else if err := status.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	table2 := ds.Table("t1_roundtrip")
+	table2.Delete(ctx)
+	loadJob2, err := table2.LoaderFrom(GCSReference{
+		URIs:         []string{gcsURI},
+		SourceFormat: Parquet,
+		Schema:       schema,
+	}).Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, err := loadJob2.Wait(ctx); err != nil {
+		t.Fatal(err)
+	} else if err := status.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a table from a Go struct, then round-trip a row through it
+	// using struct-tag based marshalling instead of ValuesSaver.
+	type structRow struct {
+		Name string `bigquery:"name"`
+		Num  int64  `bigquery:"num"`
+	}
+	structSchema, err := InferSchema(structRow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table3 := ds.Table("t1_structs")
+	table3.Delete(ctx)
+	if err := table3.Create(ctx, structSchema, TableExpiration(time.Now().Add(5*time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	if err := table3.Uploader().Put(ctx, []*structRow{{Name: "f", Num: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	structIt := table3.Read(ctx)
+	var gotStruct structRow
+	if err := structIt.Next(&gotStruct); err != nil {
+		t.Fatal(err)
+	}
+	if want := (structRow{Name: "f", Num: 5}); gotStruct != want {
+		t.Errorf("struct round-trip: got %+v, want %+v", gotStruct, want)
+	}
+
 	checkRead := func(it *RowIterator) {
 		for i := 0; true; i++ {
 			var vals ValueList
@@ -154,6 +231,23 @@ func TestIntegration(t *testing.T) {
 	}
 	checkRead(rit)
 
+	// Query with a named parameter instead of string concatenation.
+	pq := c.Query("select name, num from t1 where name = @name")
+	pq.DefaultProjectID = projID
+	pq.DefaultDatasetID = ds.id
+	pq.Parameters = []QueryParameter{{Name: "name", Value: "a"}}
+	pit, err := pq.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vals ValueList
+	if err := pit.Next(&vals); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Value{"a", 0}; !reflect.DeepEqual([]Value(vals), want) {
+		t.Errorf("parameterized query: got %v, want %v", vals, want)
+	}
+
 	// Query the long way.
 	job1, err := q.Run(ctx)
 	if err != nil {
@@ -163,11 +257,13 @@ func TestIntegration(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	// TODO(jba): poll status until job is done
-	_, err = job2.Status(ctx)
+	status, err := job2.Wait(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := status.Err(); err != nil {
+		t.Fatal(err)
+	}
 
 	rit, err = job2.Read(ctx)
 	if err != nil {
@@ -175,6 +271,17 @@ func TestIntegration(t *testing.T) {
 	}
 	checkRead(rit)
 
+	// Read the table through the BigQuery Storage API, in parallel streams.
+	session, err := table.ReadSession(ctx, ReadSessionOptions{
+		Parallelism:    2,
+		Format:         AvroFormat,
+		SelectedFields: []string{"name", "num"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRead(session.Rows(ctx))
+
 	// Test Update.
 	tm, err := table.Metadata(ctx)
 	if err != nil {
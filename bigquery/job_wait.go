@@ -0,0 +1,120 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// State is one of a sequence of states that a Job progresses through as
+// it runs.
+type State string
+
+const (
+	Pending State = "PENDING"
+	Running State = "RUNNING"
+	Done    State = "DONE"
+)
+
+// WaitOption configures Job.Wait.
+type WaitOption interface {
+	apply(*waitConfig)
+}
+
+type waitConfig struct {
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+	deadline     time.Time
+}
+
+type waitOptionFunc func(*waitConfig)
+
+func (f waitOptionFunc) apply(c *waitConfig) { f(c) }
+
+// PollInterval sets the initial delay between jobs.get polls. Job.Wait
+// doubles this delay after every poll, up to MaxBackoff.
+func PollInterval(d time.Duration) WaitOption {
+	return waitOptionFunc(func(c *waitConfig) { c.pollInterval = d })
+}
+
+// MaxBackoff caps the base delay between jobs.get polls. Job.Wait adds a
+// random jitter of up to the base delay on top, so a single wait can last
+// up to twice MaxBackoff.
+func MaxBackoff(d time.Duration) WaitOption {
+	return waitOptionFunc(func(c *waitConfig) { c.maxBackoff = d })
+}
+
+// Deadline stops Job.Wait with an error once t has passed, even if the
+// job has not reached DONE.
+func Deadline(t time.Time) WaitOption {
+	return waitOptionFunc(func(c *waitConfig) { c.deadline = t })
+}
+
+// Wait polls the job's status until it reaches state Done, using capped
+// exponential backoff with jitter between polls (by default a base delay
+// of 1s doubling up to 32s, plus a random jitter of up to the base
+// delay). It returns the final JobStatus, whose Err method reports
+// whether the job itself failed. Wait returns early with an error if ctx
+// is canceled, or if a Deadline option passes, before the job finishes.
+func (j *Job) Wait(ctx context.Context, opts ...WaitOption) (*JobStatus, error) {
+	cfg := waitConfig{
+		pollInterval: time.Second,
+		maxBackoff:   32 * time.Second,
+	}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	delay := cfg.pollInterval
+	for {
+		status, err := j.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if status.State == Done {
+			return status, nil
+		}
+		if !cfg.deadline.IsZero() && time.Now().After(cfg.deadline) {
+			return status, context.DeadlineExceeded
+		}
+		wait := delay + time.Duration(rand.Int63n(int64(delay)+1))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+		if delay > cfg.maxBackoff {
+			delay = cfg.maxBackoff
+		}
+	}
+}
+
+// Err returns the job's terminal error, if any. It is nil unless State is
+// Done and the job failed.
+func (s *JobStatus) Err() error {
+	return s.err
+}
+
+// Cancel requests that the job be canceled. This method returns without
+// waiting for cancellation to take effect on the server; poll with
+// Status or Wait to observe the job's final state.
+func (j *Job) Cancel(ctx context.Context) error {
+	call := j.c.bqs.Jobs.Cancel(j.projectID, j.jobID).Context(ctx)
+	_, err := call.Do()
+	return err
+}
@@ -0,0 +1,185 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"golang.org/x/net/context"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+// ORC is an additional DataFormat, alongside the CSV, JSON, Avro and
+// Parquet constants in load_reader.go, usable only as a load source (it
+// cannot be used to extract data from BigQuery).
+const ORC DataFormat = "ORC"
+
+// Compression is the compression codec of data to be loaded from, or
+// extracted to, GCS.
+type Compression string
+
+const (
+	None   Compression = "NONE"
+	Gzip   Compression = "GZIP"
+	Snappy Compression = "SNAPPY"
+)
+
+// GCSReference describes one or more GCS objects used as the source of a
+// load job or the destination of an extract job.
+type GCSReference struct {
+	// URIs is the list of gs:// object URIs, which may contain a single
+	// '*' wildcard character.
+	URIs []string
+
+	// SourceFormat, for a load job, or DestinationFormat, for an extract
+	// job, is the format of the GCS data. Required.
+	SourceFormat DataFormat
+
+	// Compression is the compression codec to use when writing data to
+	// GCS in an extract job (Extractor). It has no effect on a load job
+	// (Loader): load sources are decompressed automatically based on
+	// their URI extension, and the jobs.load API has no corresponding
+	// request field. Defaults to None.
+	Compression Compression
+
+	// Schema describes the data's columns. It may be nil if SourceFormat
+	// supports autodetection.
+	Schema Schema
+
+	// AutoDetect enables schema and format autodetection for CSV and
+	// JSON sources.
+	AutoDetect bool
+
+	// FieldDelimiter is the separator for fields in a CSV file. Defaults
+	// to comma.
+	FieldDelimiter string
+
+	// AllowJaggedRows accepts CSV rows that are missing trailing
+	// optional columns.
+	AllowJaggedRows bool
+
+	// MaxBadRecords is the maximum number of bad records that can be
+	// ignored before the job fails. Defaults to 0.
+	MaxBadRecords int64
+
+	// HivePartitioningOptions configures Hive-style partition layout
+	// detection for URIs that point at a partitioned GCS prefix.
+	HivePartitioningOptions *HivePartitioningOptions
+}
+
+// HivePartitioningOptions controls reading Hive-partitioned GCS data,
+// where a URI such as gs://bucket/table/dt=2018-01-01/part.parquet
+// encodes a column's value in its path.
+type HivePartitioningOptions struct {
+	// SourceURIPrefix is the common, non-partitioned GCS prefix below
+	// which partition directories start, e.g. "gs://bucket/table/".
+	SourceURIPrefix string
+}
+
+func (g *GCSReference) toBQ() *bq.JobConfigurationLoad {
+	load := &bq.JobConfigurationLoad{
+		SourceUris:      g.URIs,
+		SourceFormat:    string(g.SourceFormat),
+		Autodetect:      g.AutoDetect,
+		FieldDelimiter:  g.FieldDelimiter,
+		AllowJaggedRows: g.AllowJaggedRows,
+		MaxBadRecords:   g.MaxBadRecords,
+		Schema:          g.Schema.toBQ(),
+	}
+	if g.HivePartitioningOptions != nil {
+		load.HivePartitioningOptions = &bq.HivePartitioningOptions{
+			SourceUriPrefix: g.HivePartitioningOptions.SourceURIPrefix,
+		}
+	}
+	return load
+}
+
+// insertJob submits job via jobs.insert and wraps the result as a Job.
+func (c *Client) insertJob(ctx context.Context, job *bq.Job, projectID string) (*Job, error) {
+	res, err := c.bqs.Jobs.Insert(projectID, job).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return c.jobFromBQJob(res), nil
+}
+
+// Loader builds a load job that populates a table from one or more GCS
+// objects.
+type Loader struct {
+	// Dst is the table the load job writes to.
+	Dst *Table
+
+	// Src describes the GCS objects to load and their format.
+	Src GCSReference
+
+	// WriteDisposition specifies how existing data in Dst is treated.
+	// Defaults to WriteAppend.
+	WriteDisposition TableWriteDisposition
+
+	// CreateDisposition specifies whether Dst may be created if it does
+	// not already exist. Defaults to CreateIfNeeded.
+	CreateDisposition TableCreateDisposition
+}
+
+// TableCreateDisposition specifies whether a destination table may be
+// created by a load or query job.
+type TableCreateDisposition string
+
+const (
+	// CreateIfNeeded creates the table if it does not already exist.
+	CreateIfNeeded TableCreateDisposition = "CREATE_IF_NEEDED"
+	// CreateNever requires that the table already exist.
+	CreateNever TableCreateDisposition = "CREATE_NEVER"
+)
+
+// LoaderFrom returns a Loader that loads src into the table.
+func (t *Table) LoaderFrom(src GCSReference) *Loader {
+	return &Loader{Dst: t, Src: src}
+}
+
+// Run submits the load job for execution.
+func (l *Loader) Run(ctx context.Context) (*Job, error) {
+	load := l.Src.toBQ()
+	load.DestinationTable = l.Dst.toBQ()
+	load.WriteDisposition = string(l.WriteDisposition)
+	load.CreateDisposition = string(l.CreateDisposition)
+	job := &bq.Job{Configuration: &bq.JobConfiguration{Load: load}}
+	return l.Dst.c.insertJob(ctx, job, l.Dst.ProjectID)
+}
+
+// Extractor builds an extract job that writes a table's contents to GCS.
+type Extractor struct {
+	// Src is the table the extract job reads from.
+	Src *Table
+
+	// Dst describes the GCS objects to write and their format.
+	Dst GCSReference
+}
+
+// ExtractorTo returns an Extractor that extracts the table to dst.
+func (t *Table) ExtractorTo(dst GCSReference) *Extractor {
+	return &Extractor{Src: t, Dst: dst}
+}
+
+// Run submits the extract job for execution.
+func (e *Extractor) Run(ctx context.Context) (*Job, error) {
+	extract := &bq.JobConfigurationExtract{
+		SourceTable:       e.Src.toBQ(),
+		DestinationUris:   e.Dst.URIs,
+		DestinationFormat: string(e.Dst.SourceFormat),
+		Compression:       string(e.Dst.Compression),
+		FieldDelimiter:    e.Dst.FieldDelimiter,
+	}
+	job := &bq.Job{Configuration: &bq.JobConfiguration{Extract: extract}}
+	return e.Src.c.insertJob(ctx, job, e.Src.ProjectID)
+}
@@ -0,0 +1,176 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/context"
+	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+)
+
+// DataFormat describes the format of data read from or written to an
+// external source, such as a local reader, GCS object, or query result.
+type DataFormat string
+
+const (
+	CSV     DataFormat = "CSV"
+	JSON    DataFormat = "NEWLINE_DELIMITED_JSON"
+	Avro    DataFormat = "AVRO"
+	Parquet DataFormat = "PARQUET"
+)
+
+// TableWriteDisposition specifies how existing data in a destination
+// table is handled by a load or query job.
+type TableWriteDisposition string
+
+const (
+	// WriteAppend appends loaded rows to the table's existing data.
+	WriteAppend TableWriteDisposition = "WRITE_APPEND"
+	// WriteTruncate replaces the table's existing data with the loaded rows.
+	WriteTruncate TableWriteDisposition = "WRITE_TRUNCATE"
+	// WriteEmpty fails the job if the destination table already has data.
+	WriteEmpty TableWriteDisposition = "WRITE_EMPTY"
+)
+
+// LoadConfig describes a LoadFromReader upload.
+type LoadConfig struct {
+	// SourceFormat is the format of the data in r. Required.
+	SourceFormat DataFormat
+
+	// ChunkSize is the number of bytes uploaded per resumable-media
+	// chunk. If zero, googleapi.DefaultUploadChunkSize is used.
+	ChunkSize int
+
+	// Schema describes the data's columns. LoadFromReader does not set
+	// the load job's autodetect option, so a nil Schema does not by
+	// itself enable schema autodetection.
+	Schema Schema
+
+	// WriteDisposition specifies how existing data in the destination
+	// table is treated. Defaults to WriteAppend.
+	WriteDisposition TableWriteDisposition
+}
+
+// LoadFromReader starts a load job that streams r's contents into the
+// table using a resumable multipart upload against the jobs.insert media
+// endpoint, rather than requiring the data to be staged in GCS first. r
+// is read in cfg.ChunkSize pieces; a chunk whose upload fails is retried
+// with exponential backoff and jitter, honoring any Retry-After header
+// the service sends back on 429 or 503 responses.
+func (t *Table) LoadFromReader(ctx context.Context, r io.Reader, cfg LoadConfig) (*Job, error) {
+	chunkSize := cfg.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = googleapi.DefaultUploadChunkSize
+	}
+	job := &bq.Job{
+		Configuration: &bq.JobConfiguration{
+			Load: &bq.JobConfigurationLoad{
+				SourceFormat:     string(cfg.SourceFormat),
+				WriteDisposition: string(cfg.WriteDisposition),
+				DestinationTable: t.toBQ(),
+				Schema:           cfg.Schema.toBQ(),
+			},
+		},
+	}
+	call := t.c.bqs.Jobs.Insert(t.ProjectID, job).Media(r, googleapi.ChunkSize(chunkSize))
+	call = call.Context(ctx)
+
+	res, err := runWithChunkRetry(ctx, call.Do)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: load from reader: %v", err)
+	}
+	return t.c.jobFromBQJob(res), nil
+}
+
+// runWithChunkRetry calls do, retrying on errors that look like
+// transient failures of an in-flight resumable chunk upload (429, 503,
+// connection resets and network timeouts), backing off exponentially with jitter and
+// honoring any Retry-After header the server supplies.
+func runWithChunkRetry(ctx context.Context, do func(...googleapi.CallOption) (*bq.Job, error)) (*bq.Job, error) {
+	backoff := time.Second
+	const maxBackoff = 32 * time.Second
+	for {
+		res, err := do()
+		if err == nil {
+			return res, nil
+		}
+		retryAfter, ok := retryableChunkError(err)
+		if !ok {
+			return nil, err
+		}
+		wait := retryAfter
+		if wait == 0 {
+			wait = backoff + time.Duration(rand.Int63n(int64(backoff)))
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryableChunkError reports whether err is a transient failure worth
+// retrying a chunk upload for, and the Retry-After delay the server
+// requested, if any. A *googleapi.Error is retried on 429 or 503; a
+// connection reset or timeout surfaces instead as a *net.OpError or
+// similar net.Error, which carries no Retry-After and so always backs
+// off exponentially.
+func retryableChunkError(err error) (time.Duration, bool) {
+	if gerr, ok := err.(*googleapi.Error); ok {
+		switch gerr.Code {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			for _, h := range gerr.Header[http.CanonicalHeaderKey("Retry-After")] {
+				if secs, err := strconv.Atoi(h); err == nil {
+					return time.Duration(secs) * time.Second, true
+				}
+			}
+			return 0, true
+		}
+		return 0, false
+	}
+	if nerr, ok := err.(net.Error); ok && (nerr.Timeout() || isConnReset(err)) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// isConnReset reports whether err is a *net.OpError wrapping ECONNRESET,
+// the signature of a connection reset mid-chunk.
+func isConnReset(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	sysErr, ok := opErr.Err.(*os.SyscallError)
+	if !ok {
+		return false
+	}
+	return sysErr.Err == syscall.ECONNRESET
+}
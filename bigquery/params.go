@@ -0,0 +1,138 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/civil"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+// QueryParameter is a parameter to substitute into a parameterized
+// query. Set Name for a named parameter (`@foo`); leave it empty for a
+// positional parameter (`?`), matched against Parameters in order.
+type QueryParameter struct {
+	Name  string
+	Value interface{}
+}
+
+func (p QueryParameter) toBQ() (*bq.QueryParameter, error) {
+	ptype, pvalue, err := paramType(reflect.ValueOf(p.Value))
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: query parameter %q: %v", p.Name, err)
+	}
+	return &bq.QueryParameter{
+		Name:           p.Name,
+		ParameterType:  ptype,
+		ParameterValue: pvalue,
+	}, nil
+}
+
+func paramType(v reflect.Value) (*bq.QueryParameterType, *bq.QueryParameterValue, error) {
+	if !v.IsValid() {
+		return nil, nil, fmt.Errorf("nil parameter value")
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return &bq.QueryParameterType{Type: "TIMESTAMP"},
+			&bq.QueryParameterValue{Value: x.Format(timestampFormat)}, nil
+	case civil.Date:
+		return &bq.QueryParameterType{Type: "DATE"},
+			&bq.QueryParameterValue{Value: x.String()}, nil
+	case civil.Time:
+		return &bq.QueryParameterType{Type: "TIME"},
+			&bq.QueryParameterValue{Value: x.String()}, nil
+	case civil.DateTime:
+		return &bq.QueryParameterType{Type: "DATETIME"},
+			&bq.QueryParameterValue{Value: x.String()}, nil
+	case []byte:
+		return &bq.QueryParameterType{Type: "BYTES"},
+			&bq.QueryParameterValue{Value: string(x)}, nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return &bq.QueryParameterType{Type: "STRING"},
+			&bq.QueryParameterValue{Value: v.String()}, nil
+	case reflect.Bool:
+		return &bq.QueryParameterType{Type: "BOOL"},
+			&bq.QueryParameterValue{Value: fmt.Sprintf("%t", v.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &bq.QueryParameterType{Type: "INT64"},
+			&bq.QueryParameterValue{Value: fmt.Sprintf("%d", v.Int())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &bq.QueryParameterType{Type: "FLOAT64"},
+			&bq.QueryParameterValue{Value: fmt.Sprintf("%g", v.Float())}, nil
+	case reflect.Slice, reflect.Array:
+		// Derive the element type from a live element when possible: for
+		// a slice of pointers, reflect.Zero(Elem()) is a nil pointer,
+		// which paramType always rejects, even though every actual
+		// element in a non-empty slice may be non-nil.
+		elemSample := reflect.Zero(v.Type().Elem())
+		if v.Len() > 0 {
+			elemSample = v.Index(0)
+		}
+		elemType, _, err := paramType(elemSample)
+		if err != nil {
+			return nil, nil, err
+		}
+		var elems []*bq.QueryParameterValue
+		for i := 0; i < v.Len(); i++ {
+			_, ev, err := paramType(v.Index(i))
+			if err != nil {
+				return nil, nil, err
+			}
+			elems = append(elems, ev)
+		}
+		return &bq.QueryParameterType{Type: "ARRAY", ArrayType: elemType},
+			&bq.QueryParameterValue{ArrayValues: elems}, nil
+	case reflect.Struct:
+		return structParamType(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil, fmt.Errorf("nil pointer parameter value")
+		}
+		return paramType(v.Elem())
+	default:
+		return nil, nil, fmt.Errorf("unsupported parameter type %s", v.Type())
+	}
+}
+
+func structParamType(v reflect.Value) (*bq.QueryParameterType, *bq.QueryParameterValue, error) {
+	ptype := &bq.QueryParameterType{Type: "STRUCT"}
+	pvalue := &bq.QueryParameterValue{StructValues: map[string]bq.QueryParameterValue{}}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := parseStructTag(f)
+		if tag.skip {
+			continue
+		}
+		ft, fv, err := paramType(v.Field(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		ptype.StructTypes = append(ptype.StructTypes, &bq.QueryParameterTypeStructTypes{Name: tag.name, Type: ft})
+		pvalue.StructValues[tag.name] = *fv
+	}
+	return ptype, pvalue, nil
+}
+
+const timestampFormat = "2006-01-02 15:04:05.999999-07:00"
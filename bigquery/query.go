@@ -0,0 +1,81 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"golang.org/x/net/context"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+// Query represents a query to be executed. Use Client.Query to create
+// one.
+type Query struct {
+	// q is the text of the query.
+	q string
+
+	client *Client
+
+	// DefaultProjectID and DefaultDatasetID, if set, are used to resolve
+	// any unqualified table names in the query.
+	DefaultProjectID string
+	DefaultDatasetID string
+
+	queryConfig
+}
+
+// Query creates a Query for the given query string. UseStandardSQL
+// defaults to true.
+func (c *Client) Query(q string) *Query {
+	return &Query{
+		q:      q,
+		client: c,
+		queryConfig: queryConfig{
+			UseStandardSQL: true,
+			UseQueryCache:  true,
+		},
+	}
+}
+
+// Run submits the query for asynchronous execution and returns the
+// resulting Job, which can be polled with Job.Wait or Job.Status.
+func (q *Query) Run(ctx context.Context) (*Job, error) {
+	qc, err := q.queryConfig.toBQ()
+	if err != nil {
+		return nil, err
+	}
+	qc.Query = q.q
+	if q.DefaultProjectID != "" || q.DefaultDatasetID != "" {
+		qc.DefaultDataset = &bq.DatasetReference{
+			ProjectId: q.DefaultProjectID,
+			DatasetId: q.DefaultDatasetID,
+		}
+	}
+	job := &bq.Job{Configuration: &bq.JobConfiguration{Query: qc}}
+	q.queryConfig.applyToJob(job)
+	return q.client.insertJob(ctx, job, q.DefaultProjectID)
+}
+
+// Read submits the query and blocks until it completes, returning a
+// RowIterator over its results.
+func (q *Query) Read(ctx context.Context) (*RowIterator, error) {
+	job, err := q.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := job.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return job.Read(ctx)
+}
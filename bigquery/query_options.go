@@ -0,0 +1,112 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+// queryConfig holds the fields added to Query so that query jobs can
+// carry parameters, dry runs, and other safety options instead of
+// relying on callers to build raw SQL via string concatenation.
+//
+// Parameters are the query's bound parameters, either all named (@foo)
+// or all positional (?); Query.Run rejects a mix of the two before the
+// job is submitted.
+//
+// UseStandardSQL selects standard SQL over legacy SQL. It defaults to
+// true, matching every other entry point added to this package since
+// standard SQL became the default dialect.
+//
+// UseQueryCache and MaximumBytesBilled map directly to the identically
+// named fields of the job's query configuration, and DryRun and Labels to
+// those of the job configuration itself; see the BigQuery documentation
+// for their semantics.
+type queryConfig struct {
+	Parameters         []QueryParameter
+	UseStandardSQL     bool
+	UseQueryCache      bool
+	DryRun             bool
+	MaximumBytesBilled int64
+	Labels             map[string]string
+}
+
+// toBQ validates that Parameters are either all named or all positional,
+// then renders the config into a query job configuration.
+func (c *queryConfig) toBQ() (*bq.JobConfigurationQuery, error) {
+	named, positional := 0, 0
+	var params []*bq.QueryParameter
+	for _, p := range c.Parameters {
+		if p.Name == "" {
+			positional++
+		} else {
+			named++
+		}
+		bqp, err := p.toBQ()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, bqp)
+	}
+	if named > 0 && positional > 0 {
+		return nil, fmt.Errorf("bigquery: query parameters must be all named or all positional, not a mix")
+	}
+	mode := "NAMED"
+	if positional > 0 {
+		mode = "POSITIONAL"
+	}
+	useCache := c.UseQueryCache
+	return &bq.JobConfigurationQuery{
+		QueryParameters:    params,
+		ParameterMode:      mode,
+		UseLegacySql:       !c.UseStandardSQL,
+		ForceSendFields:    []string{"UseLegacySql"},
+		UseQueryCache:      &useCache,
+		MaximumBytesBilled: c.MaximumBytesBilled,
+	}, nil
+}
+
+// applyToJob renders the config's DryRun and Labels fields, which live at
+// the JobConfiguration level rather than under JobConfigurationQuery,
+// onto job.
+func (c *queryConfig) applyToJob(job *bq.Job) {
+	job.Configuration.DryRun = c.DryRun
+	job.Configuration.Labels = c.Labels
+}
+
+// QueryStatistics reports the subset of a completed query job's
+// statistics that are useful for deciding whether a query was cheap or
+// free to run.
+type QueryStatistics struct {
+	// TotalBytesProcessed is the total number of bytes the query would
+	// process if run without its result being served from cache.
+	TotalBytesProcessed int64
+
+	// CacheHit reports whether the query results were served from the
+	// query cache instead of executing the query.
+	CacheHit bool
+}
+
+func bqToQueryStatistics(s *bq.JobStatistics2) *QueryStatistics {
+	if s == nil {
+		return nil
+	}
+	return &QueryStatistics{
+		TotalBytesProcessed: s.TotalBytesProcessed,
+		CacheHit:            s.CacheHit,
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/linkedin/goavro/v2"
+)
+
+// schemaFromAvro translates an Avro JSON schema string, as returned in a
+// CreateReadSession response, into a Schema so the rest of the package
+// never has to special-case the BigQuery Storage API's wire schema.
+func schemaFromAvro(avroSchemaJSON string) (Schema, error) {
+	codec, err := goavro.NewCodec(avroSchemaJSON)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: parsing avro schema: %v", err)
+	}
+	fields, ok := codec.Schema()["fields"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("bigquery: avro schema has no top-level record fields")
+	}
+	var schema Schema
+	for _, f := range fields {
+		m := f.(map[string]interface{})
+		fs, err := fieldSchemaFromAvroType(m["name"].(string), m["type"])
+		if err != nil {
+			return nil, err
+		}
+		schema = append(schema, fs)
+	}
+	return schema, nil
+}
+
+// schemaFromArrowIPC translates a serialized Arrow schema message into a
+// Schema, mirroring schemaFromAvro for the Arrow wire format.
+func schemaFromArrowIPC(serialized []byte) (Schema, error) {
+	r, err := ipc.NewReader(bytes.NewReader(serialized))
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: parsing arrow schema: %v", err)
+	}
+	var schema Schema
+	for _, f := range r.Schema().Fields() {
+		fs, err := fieldSchemaFromArrowType(f.Name, f.Type)
+		if err != nil {
+			return nil, err
+		}
+		schema = append(schema, fs)
+	}
+	return schema, nil
+}
+
+// decodeAvroRows decodes a batch of Avro-binary-encoded rows against
+// schema, returning one []Value per row in schema field order.
+func decodeAvroRows(schema Schema, serializedRows []byte) ([][]Value, error) {
+	codec, err := goavro.NewCodecForStandardJSONFull(avroRecordSchemaJSON(schema))
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: rebuilding avro codec: %v", err)
+	}
+	var out [][]Value
+	buf := serializedRows
+	for len(buf) > 0 {
+		native, rest, err := codec.NativeFromBinary(buf)
+		if err != nil {
+			return nil, fmt.Errorf("bigquery: decoding avro row: %v", err)
+		}
+		row, err := valuesFromAvroNative(schema, native.(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+		buf = rest
+	}
+	return out, nil
+}
+
+// valuesFromAvroNative converts one Avro-decoded record into a []Value
+// ordered to match schema, reusing the field names both sides agree on.
+// TIMESTAMP/DATE/DATETIME/TIME fields arrive from goavro as the raw
+// integer encoding of their Avro logical type (micros or days since the
+// epoch); convertAvroLogicalValue turns those back into the time.Time
+// and civil.* values the rest of the package uses for those FieldTypes.
+func valuesFromAvroNative(schema Schema, native map[string]interface{}) ([]Value, error) {
+	row := make([]Value, len(schema))
+	for i, fs := range schema {
+		v, ok := native[fs.Name]
+		if !ok {
+			return nil, fmt.Errorf("bigquery: avro row missing field %q", fs.Name)
+		}
+		cv, err := convertAvroLogicalValue(fs.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("bigquery: field %s: %v", fs.Name, err)
+		}
+		row[i] = cv
+	}
+	return row, nil
+}
+
+// convertAvroLogicalValue converts v, as decoded by goavro from the wire
+// encoding of an Avro logical type, into the Value representation
+// ftype's FieldType normally holds. Non-logical types pass through
+// unchanged.
+func convertAvroLogicalValue(ftype FieldType, v interface{}) (Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch ftype {
+	case TimestampFieldType:
+		micros, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("timestamp-micros value has unexpected type %T", v)
+		}
+		return time.Unix(0, micros*int64(time.Microsecond)).UTC(), nil
+	case DateFieldType:
+		days, ok := v.(int32)
+		if !ok {
+			return nil, fmt.Errorf("date value has unexpected type %T", v)
+		}
+		return civil.DateOf(time.Unix(0, 0).UTC().AddDate(0, 0, int(days))), nil
+	case TimeFieldType:
+		micros, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("time-micros value has unexpected type %T", v)
+		}
+		return civil.TimeOf(time.Unix(0, micros*int64(time.Microsecond)).UTC()), nil
+	case DateTimeFieldType:
+		micros, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("local-timestamp-micros value has unexpected type %T", v)
+		}
+		return civil.DateTimeOf(time.Unix(0, micros*int64(time.Microsecond)).UTC()), nil
+	default:
+		return Value(v), nil
+	}
+}
+
+// decodeArrowRecordBatch decodes a single serialized Arrow RecordBatch
+// against schema, returning one []Value per row in schema field order.
+func decodeArrowRecordBatch(schema Schema, serialized []byte) ([][]Value, error) {
+	r, err := ipc.NewReader(bytes.NewReader(serialized))
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: decoding arrow record batch: %v", err)
+	}
+	var out [][]Value
+	for r.Next() {
+		rec := r.Record()
+		for row := 0; row < int(rec.NumRows()); row++ {
+			vals := make([]Value, len(schema))
+			for col := 0; col < int(rec.NumCols()); col++ {
+				v, err := valueFromArrowColumn(rec.Column(col), row)
+				if err != nil {
+					return nil, err
+				}
+				vals[col] = v
+			}
+			out = append(out, vals)
+		}
+	}
+	return out, r.Err()
+}
+
+func valueFromArrowColumn(col array.Interface, row int) (Value, error) {
+	if col.IsNull(row) {
+		return nil, nil
+	}
+	switch c := col.(type) {
+	case *array.String:
+		return c.Value(row), nil
+	case *array.Int64:
+		return c.Value(row), nil
+	case *array.Float64:
+		return c.Value(row), nil
+	case *array.Boolean:
+		return c.Value(row), nil
+	case *array.Binary:
+		return c.Value(row), nil
+	case *array.Timestamp:
+		unit := c.DataType().(*arrow.TimestampType).Unit
+		return arrowTimestampToTime(unit, int64(c.Value(row))), nil
+	case *array.Date32:
+		return civil.DateOf(time.Unix(int64(c.Value(row))*24*3600, 0).UTC()), nil
+	case *array.Date64:
+		return civil.DateOf(time.Unix(0, int64(c.Value(row))*int64(time.Millisecond)).UTC()), nil
+	default:
+		return nil, fmt.Errorf("bigquery: unsupported arrow column type %T", col)
+	}
+}
+
+// arrowTimestampToTime converts an Arrow TIMESTAMP value, stored as an
+// integer count of unit since the Unix epoch, to a time.Time in UTC.
+func arrowTimestampToTime(unit arrow.TimeUnit, v int64) time.Time {
+	switch unit {
+	case arrow.Second:
+		return time.Unix(v, 0).UTC()
+	case arrow.Millisecond:
+		return time.Unix(0, v*int64(time.Millisecond)).UTC()
+	case arrow.Microsecond:
+		return time.Unix(0, v*int64(time.Microsecond)).UTC()
+	default: // arrow.Nanosecond
+		return time.Unix(0, v).UTC()
+	}
+}
+
+// fieldSchemaFromArrowType translates an Arrow field type into a
+// FieldSchema. TIMESTAMP and the two DATE widths are handled explicitly
+// so TIMESTAMP/DATE columns decode into time.Time/civil.Date rather than
+// failing with "unsupported arrow field type", as they did before
+// BigQuery Storage API sessions could return these near-universal
+// column types.
+func fieldSchemaFromArrowType(name string, t arrow.DataType) (*FieldSchema, error) {
+	fs := &FieldSchema{Name: name}
+	switch t.ID() {
+	case arrow.STRING:
+		fs.Type = StringFieldType
+	case arrow.INT64:
+		fs.Type = IntegerFieldType
+	case arrow.FLOAT64:
+		fs.Type = FloatFieldType
+	case arrow.BOOL:
+		fs.Type = BooleanFieldType
+	case arrow.BINARY:
+		fs.Type = BytesFieldType
+	case arrow.TIMESTAMP:
+		fs.Type = TimestampFieldType
+	case arrow.DATE32, arrow.DATE64:
+		fs.Type = DateFieldType
+	default:
+		return nil, fmt.Errorf("bigquery: unsupported arrow field type %s for %q", t.Name(), name)
+	}
+	return fs, nil
+}
+
+// fieldSchemaFromAvroType translates a parsed Avro field type into a
+// FieldSchema. BigQuery encodes TIMESTAMP/DATE/DATETIME/TIME columns as
+// Avro primitives annotated with a logicalType (e.g. a "long" field with
+// logicalType "timestamp-micros"); those are checked before the bare
+// primitive name so such columns don't get silently typed as INTEGER or
+// STRING.
+func fieldSchemaFromAvroType(name string, avroType interface{}) (*FieldSchema, error) {
+	fs := &FieldSchema{Name: name}
+	typeName, logicalType, nullable := avroTypeNameAndLogicalType(avroType)
+	fs.Required = !nullable
+	switch logicalType {
+	case "timestamp-micros", "timestamp-millis":
+		fs.Type = TimestampFieldType
+		return fs, nil
+	case "date":
+		fs.Type = DateFieldType
+		return fs, nil
+	case "time-micros", "time-millis":
+		fs.Type = TimeFieldType
+		return fs, nil
+	case "local-timestamp-micros", "local-timestamp-millis":
+		fs.Type = DateTimeFieldType
+		return fs, nil
+	}
+	switch typeName {
+	case "string":
+		fs.Type = StringFieldType
+	case "long", "int":
+		fs.Type = IntegerFieldType
+	case "double", "float":
+		fs.Type = FloatFieldType
+	case "boolean":
+		fs.Type = BooleanFieldType
+	case "bytes":
+		fs.Type = BytesFieldType
+	default:
+		return nil, fmt.Errorf("bigquery: unsupported avro field type %q for %q", typeName, name)
+	}
+	return fs, nil
+}
+
+// avroTypeNameAndLogicalType unwraps the ["null", T] union Avro uses for
+// nullable fields, then resolves T to its primitive type name and
+// logicalType annotation, if any. T may be a bare primitive name
+// ("long") or an annotated type object ({"type":"long",
+// "logicalType":"timestamp-micros"}).
+func avroTypeNameAndLogicalType(avroType interface{}) (typeName, logicalType string, nullable bool) {
+	switch t := avroType.(type) {
+	case string:
+		return t, "", false
+	case []interface{}:
+		for _, alt := range t {
+			if s, ok := alt.(string); ok && s == "null" {
+				continue
+			}
+			name, logical, _ := avroTypeNameAndLogicalType(alt)
+			return name, logical, true
+		}
+	case map[string]interface{}:
+		name, _ := t["type"].(string)
+		logical, _ := t["logicalType"].(string)
+		return name, logical, false
+	}
+	return "", "", false
+}
+
+// avroRecordSchemaJSON rebuilds a minimal Avro record schema from Schema.
+// decodeAvroRows uses it to build a codec for every batch, since the
+// codec parsed at session creation is not kept.
+func avroRecordSchemaJSON(schema Schema) string {
+	var buf bytes.Buffer
+	buf.WriteString(`{"type":"record","name":"Row","fields":[`)
+	for i, fs := range schema {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, `{"name":%q,"type":%q}`, fs.Name, avroTypeFor(fs.Type))
+	}
+	buf.WriteString(`]}`)
+	return buf.String()
+}
+
+// avroTypeFor returns the physical Avro wire type used to encode t. This
+// is the bare primitive only: TIMESTAMP and TIME are physically "long"
+// (encoding microseconds), and DATE is physically "int" (encoding days),
+// regardless of the logicalType annotation that would normally label
+// them — the annotation affects decoding semantics, not the bytes on
+// the wire, and the codec rebuilt here only needs to get the bytes
+// right, since decoding to the right semantic type happens afterward in
+// convertAvroLogicalValue.
+func avroTypeFor(t FieldType) string {
+	switch t {
+	case IntegerFieldType, TimestampFieldType, TimeFieldType, DateTimeFieldType:
+		return "long"
+	case DateFieldType:
+		return "int"
+	case FloatFieldType:
+		return "double"
+	case BooleanFieldType:
+		return "boolean"
+	case BytesFieldType:
+		return "bytes"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+
+	storage "cloud.google.com/go/bigquery/storage/apiv1beta1"
+	"golang.org/x/net/context"
+	"google.golang.org/api/iterator"
+	storagepb "google.golang.org/genproto/googleapis/cloud/bigquery/storage/v1beta1"
+)
+
+// storageReadClient lazily dials the BigQuery Storage read client and
+// caches it on the Client, mirroring how the package already caches its
+// other RPC clients.
+func (c *Client) storageReadClient(ctx context.Context) (storage.BigQueryReadClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.storageClient != nil {
+		return c.storageClient, nil
+	}
+	sc, err := storage.NewBigQueryReadClient(ctx, c.clientOptions...)
+	if err != nil {
+		return nil, err
+	}
+	c.storageClient = sc
+	return sc, nil
+}
+
+// schemaFromReadSession recovers a Schema from the Avro or Arrow schema
+// embedded in a CreateReadSession response, so downstream decoding can
+// reuse the existing Value machinery.
+func schemaFromReadSession(rs *storagepb.ReadSession) (Schema, error) {
+	switch s := rs.Schema.(type) {
+	case *storagepb.ReadSession_AvroSchema:
+		return schemaFromAvro(s.AvroSchema.Schema)
+	case *storagepb.ReadSession_ArrowSchema:
+		return schemaFromArrowIPC(s.ArrowSchema.SerializedSchema)
+	default:
+		return nil, fmt.Errorf("bigquery: read session %s has no schema", rs.Name)
+	}
+}
+
+// decodeRowBatch decodes one ReadRowsResponse into a slice of rows, each
+// a []Value in schema's field order. The two wire formats are decoded by
+// format-specific helpers so RowIterator never has to know which one the
+// session negotiated.
+func decodeRowBatch(schema Schema, resp *storagepb.ReadRowsResponse) ([][]Value, error) {
+	switch rows := resp.Rows.(type) {
+	case *storagepb.ReadRowsResponse_AvroRows:
+		return decodeAvroRows(schema, rows.AvroRows.SerializedBinaryRows)
+	case *storagepb.ReadRowsResponse_ArrowRecordBatch:
+		return decodeArrowRecordBatch(schema, rows.ArrowRecordBatch.SerializedRecordBatch)
+	default:
+		return nil, fmt.Errorf("bigquery: unsupported row encoding in read response")
+	}
+}
+
+// newRowIteratorFromChannel adapts a channel of decoded rows into a
+// RowIterator, so BigQuery Storage sessions can be consumed with the same
+// Next(dst interface{}) call as Table.Read and Job.Read.
+func newRowIteratorFromChannel(schema Schema, rows <-chan []Value, errc <-chan error) *RowIterator {
+	it := &RowIterator{Schema: schema}
+	it.next = func() ([]Value, error) {
+		select {
+		case row, ok := <-rows:
+			if !ok {
+				select {
+				case err := <-errc:
+					return nil, err
+				default:
+					return nil, iterator.Done
+				}
+			}
+			return row, nil
+		case err := <-errc:
+			return nil, err
+		}
+	}
+	return it
+}
@@ -0,0 +1,188 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"io"
+
+	storage "cloud.google.com/go/bigquery/storage/apiv1beta1"
+	"golang.org/x/net/context"
+	storagepb "google.golang.org/genproto/googleapis/cloud/bigquery/storage/v1beta1"
+)
+
+// StorageFormat specifies the encoding of the rows returned by a read session.
+type StorageFormat int
+
+const (
+	// AvroFormat requests that stream data be serialized using the Avro
+	// binary format.
+	AvroFormat StorageFormat = iota
+	// ArrowFormat requests that stream data be serialized using Arrow.
+	ArrowFormat
+)
+
+// ReadSessionOptions configures a Table.ReadSession call.
+type ReadSessionOptions struct {
+	// Parallelism is the requested number of streams the session should be
+	// split across. The server may return fewer streams than requested.
+	Parallelism int
+
+	// Format is the wire format used to serialize each stream's rows.
+	// Defaults to AvroFormat.
+	Format StorageFormat
+
+	// SelectedFields restricts the columns returned by the session. An
+	// empty slice selects every column in the table.
+	SelectedFields []string
+
+	// RowRestriction is a SQL-like filter applied server-side, for example
+	// `state = "WA"`. An empty string selects every row.
+	RowRestriction string
+}
+
+// ReadSession represents an open BigQuery Storage API read session over a
+// table. A session is split into one or more independent Streams that can
+// be consumed in parallel.
+type ReadSession struct {
+	t       *Table
+	name    string
+	schema  Schema
+	streams []*ReadStream
+	client  storage.BigQueryReadClient
+}
+
+// ReadStream is a single partition of a ReadSession's rows.
+type ReadStream struct {
+	session *ReadSession
+	name    string
+}
+
+// ReadSession creates a new BigQuery Storage API read session over the
+// table, honoring any selected fields or row restriction in opts. Use
+// Rows to consume the session as a single merged RowIterator, or Streams
+// to consume each partition independently for parallel reads.
+func (t *Table) ReadSession(ctx context.Context, opts ReadSessionOptions) (*ReadSession, error) {
+	client, err := t.c.storageReadClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: creating storage read client: %v", err)
+	}
+	req := &storagepb.CreateReadSessionRequest{
+		Parent: fmt.Sprintf("projects/%s", t.ProjectID),
+		ReadSession: &storagepb.ReadSession{
+			Table:      fmt.Sprintf("projects/%s/datasets/%s/tables/%s", t.ProjectID, t.DatasetID, t.TableID),
+			DataFormat: dataFormatProto(opts.Format),
+			ReadOptions: &storagepb.TableReadOptions{
+				SelectedFields: opts.SelectedFields,
+				RowRestriction: opts.RowRestriction,
+			},
+		},
+		MaxStreamCount: int32(opts.Parallelism),
+	}
+	rs, err := client.CreateReadSession(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: creating read session: %v", err)
+	}
+	schema, err := schemaFromReadSession(rs)
+	if err != nil {
+		return nil, err
+	}
+	session := &ReadSession{
+		t:      t,
+		name:   rs.Name,
+		schema: schema,
+		client: client,
+	}
+	for _, s := range rs.Streams {
+		session.streams = append(session.streams, &ReadStream{session: session, name: s.Name})
+	}
+	return session, nil
+}
+
+// Streams returns the session's independent read partitions. Each stream
+// can be read concurrently from a different goroutine.
+func (s *ReadSession) Streams() []*ReadStream {
+	return s.streams
+}
+
+// Rows returns a RowIterator that reads every stream in the session in
+// sequence and decodes each batch into the module's Value model, so it
+// can be consumed the same way as Table.Read or Job.Read.
+func (s *ReadSession) Rows(ctx context.Context) *RowIterator {
+	return newMergedRowIterator(ctx, s.schema, s.streams)
+}
+
+// Rows returns a RowIterator over this stream's rows alone.
+func (s *ReadStream) Rows(ctx context.Context) *RowIterator {
+	return newStreamRowIterator(ctx, s.session.schema, s)
+}
+
+func newMergedRowIterator(ctx context.Context, schema Schema, streams []*ReadStream) *RowIterator {
+	pages := make(chan []Value)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(pages)
+		for _, s := range streams {
+			if err := decodeStreamInto(ctx, s, pages); err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+	return newRowIteratorFromChannel(schema, pages, errc)
+}
+
+func newStreamRowIterator(ctx context.Context, schema Schema, s *ReadStream) *RowIterator {
+	return newMergedRowIterator(ctx, schema, []*ReadStream{s})
+}
+
+// decodeStreamInto reads batches off a single stream, decoding Arrow or
+// Avro rows into the module's Value representation and sending each
+// decoded row on rows. Per the standard gRPC server-streaming contract,
+// the stream signals clean completion with io.EOF from Recv, not with a
+// package-specific sentinel.
+func decodeStreamInto(ctx context.Context, s *ReadStream, rows chan<- []Value) error {
+	stream, err := s.session.client.ReadRows(ctx, &storagepb.ReadRowsRequest{ReadStream: s.name})
+	if err != nil {
+		return fmt.Errorf("bigquery: opening stream %s: %v", s.name, err)
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		decoded, err := decodeRowBatch(s.session.schema, resp)
+		if err != nil {
+			return err
+		}
+		for _, r := range decoded {
+			select {
+			case rows <- r:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func dataFormatProto(f StorageFormat) storagepb.DataFormat {
+	if f == ArrowFormat {
+		return storagepb.DataFormat_ARROW
+	}
+	return storagepb.DataFormat_AVRO
+}
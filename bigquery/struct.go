@@ -0,0 +1,385 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"golang.org/x/net/context"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+// ValueSaver is implemented by types that can be uploaded with
+// Uploader.Put, producing the row and insert ID that will be sent to
+// BigQuery. ValuesSaver implements it directly; Uploader.Put also
+// accepts plain struct values by wrapping them in structSaver.
+type ValueSaver interface {
+	Save() (row map[string]Value, insertID string, err error)
+}
+
+// ValueLoader is implemented by types that can decode a BigQuery row
+// themselves, such as ValueList. RowIterator.Next falls back to
+// structLoader for any dst that doesn't implement it.
+type ValueLoader interface {
+	Load(v []Value, s Schema) error
+}
+
+// Uploader uploads rows to a table, via Put, using the tabledata.insertAll
+// streaming API.
+type Uploader struct {
+	t *Table
+}
+
+// Uploader returns an Uploader bound to the table.
+func (t *Table) Uploader() *Uploader {
+	return &Uploader{t: t}
+}
+
+// Put uploads one or more rows to the table. src must be a ValueSaver, a
+// slice of ValueSavers, a struct, or a slice of structs (or pointers to
+// either); plain structs are converted to rows with structSaver, so
+// Uploader.Put(ctx, []MyStruct{...}) works the same as passing
+// *ValuesSaver values explicitly.
+func (u *Uploader) Put(ctx context.Context, src interface{}) error {
+	savers, err := valueSavers(src)
+	if err != nil {
+		return err
+	}
+	return u.putValueSavers(ctx, savers)
+}
+
+// valueSavers normalizes src, a ValueSaver, struct, or slice of either,
+// into a []ValueSaver, wrapping any element that isn't already a
+// ValueSaver in structSaver.
+func valueSavers(src interface{}) ([]ValueSaver, error) {
+	if vs, ok := src.(ValueSaver); ok {
+		return []ValueSaver{vs}, nil
+	}
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Slice {
+		return []ValueSaver{structSaver{v: src}}, nil
+	}
+	savers := make([]ValueSaver, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i).Interface()
+		if vs, ok := elem.(ValueSaver); ok {
+			savers[i] = vs
+			continue
+		}
+		savers[i] = structSaver{v: elem}
+	}
+	return savers, nil
+}
+
+// putValueSavers sends savers to the tabledata.insertAll endpoint.
+func (u *Uploader) putValueSavers(ctx context.Context, savers []ValueSaver) error {
+	req := &bq.TableDataInsertAllRequest{}
+	for _, s := range savers {
+		row, insertID, err := s.Save()
+		if err != nil {
+			return err
+		}
+		jsonRow := make(map[string]interface{}, len(row))
+		for k, v := range row {
+			jsonRow[k] = v
+		}
+		req.Rows = append(req.Rows, &bq.TableDataInsertAllRequestRows{
+			InsertId: insertID,
+			Json:     jsonRow,
+		})
+	}
+	res, err := u.t.c.bqs.Tabledata.InsertAll(u.t.ProjectID, u.t.DatasetID, u.t.TableID, req).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if len(res.InsertErrors) > 0 {
+		return fmt.Errorf("bigquery: Put: %d rows failed to insert", len(res.InsertErrors))
+	}
+	return nil
+}
+
+// Next loads the next row into dst. If dst implements ValueLoader, its
+// Load method decodes the row, matching the existing *ValueList
+// behavior. Otherwise dst must be a pointer to a struct, which is
+// populated by field name via structLoader.
+func (it *RowIterator) Next(dst interface{}) error {
+	row, err := it.next()
+	if err != nil {
+		return err
+	}
+	if vl, ok := dst.(ValueLoader); ok {
+		return vl.Load(row, it.Schema)
+	}
+	return structLoader(it.Schema, row, dst)
+}
+
+// structTag is the parsed form of a `bigquery:"..."` struct field tag.
+type structTag struct {
+	name     string
+	nullable bool
+	insertID bool
+	skip     bool
+}
+
+// parseStructTag parses the bigquery struct tag on f, defaulting name to
+// f's own name (lower-cased) when the tag omits one. A tag of "-" skips
+// the field entirely, matching the convention used by encoding/json.
+func parseStructTag(f reflect.StructField) structTag {
+	tag := f.Tag.Get("bigquery")
+	if tag == "-" {
+		return structTag{skip: true}
+	}
+	st := structTag{name: strings.ToLower(f.Name)}
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" {
+		st.name = parts[0]
+	}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "nullable":
+			st.nullable = true
+		case "insertid":
+			st.insertID = true
+		}
+	}
+	return st
+}
+
+// InferSchema derives a Schema from the exported fields of the struct
+// type of v (v may be a struct or a pointer to one), following the same
+// `bigquery:"name,nullable"` tag conventions as Uploader.Put. Embedded
+// structs are flattened into their parent; nested non-embedded structs
+// become RECORD fields.
+func InferSchema(v interface{}) (Schema, error) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bigquery: InferSchema: %s is not a struct or pointer to struct", t)
+	}
+	return inferStruct(t)
+}
+
+func inferStruct(t reflect.Type) (Schema, error) {
+	var schema Schema
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		tag := parseStructTag(f)
+		if tag.skip || tag.insertID {
+			continue
+		}
+		ft := f.Type
+		if f.Anonymous && ft.Kind() == reflect.Struct {
+			embedded, err := inferStruct(ft)
+			if err != nil {
+				return nil, err
+			}
+			schema = append(schema, embedded...)
+			continue
+		}
+		fs, err := fieldSchemaForType(tag.name, ft, tag.nullable)
+		if err != nil {
+			return nil, fmt.Errorf("bigquery: InferSchema: field %s: %v", f.Name, err)
+		}
+		schema = append(schema, fs)
+	}
+	return schema, nil
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	dateType  = reflect.TypeOf(civil.Date{})
+	byteSlice = reflect.TypeOf([]byte(nil))
+)
+
+func fieldSchemaForType(name string, t reflect.Type, nullable bool) (*FieldSchema, error) {
+	fs := &FieldSchema{Name: name, Required: !nullable}
+	switch {
+	case t == timeType:
+		fs.Type = TimestampFieldType
+	case t == dateType:
+		fs.Type = DateFieldType
+	case t == byteSlice:
+		fs.Type = BytesFieldType
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		fs.Repeated = true
+		fs.Required = false
+		elem, err := fieldSchemaForType(name, t.Elem(), false)
+		if err != nil {
+			return nil, err
+		}
+		fs.Type = elem.Type
+		fs.Schema = elem.Schema
+	case t.Kind() == reflect.Struct:
+		fs.Type = RecordFieldType
+		nested, err := inferStruct(t)
+		if err != nil {
+			return nil, err
+		}
+		fs.Schema = nested
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct:
+		return fieldSchemaForType(name, t.Elem(), true)
+	default:
+		switch t.Kind() {
+		case reflect.String:
+			fs.Type = StringFieldType
+		case reflect.Bool:
+			fs.Type = BooleanFieldType
+		case reflect.Float32, reflect.Float64:
+			fs.Type = FloatFieldType
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fs.Type = IntegerFieldType
+		default:
+			return nil, fmt.Errorf("cannot infer a schema field for type %s", t)
+		}
+	}
+	return fs, nil
+}
+
+// structSaver adapts a struct value to the ValueSaver interface expected
+// by Uploader.Put, deriving its row and InsertID from struct tags via
+// structToValues.
+type structSaver struct {
+	v interface{}
+}
+
+func (s structSaver) Save() (map[string]Value, string, error) {
+	return structToValues(reflect.ValueOf(s.v))
+}
+
+// structToValues flattens v, a struct or pointer to struct, into a
+// row keyed by field name and an InsertID taken from whichever field is
+// tagged `bigquery:",insertid"`.
+func structToValues(v reflect.Value) (map[string]Value, string, error) {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, "", fmt.Errorf("bigquery: %s is not a struct or pointer to struct", v.Type())
+	}
+	row := map[string]Value{}
+	var insertID string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := parseStructTag(f)
+		if tag.skip {
+			continue
+		}
+		fv := v.Field(i)
+		if tag.insertID {
+			insertID, _ = fv.Interface().(string)
+			continue
+		}
+		if f.Anonymous && fv.Kind() == reflect.Struct {
+			embedded, embeddedID, err := structToValues(fv)
+			if err != nil {
+				return nil, "", err
+			}
+			if insertID == "" {
+				insertID = embeddedID
+			}
+			for k, ev := range embedded {
+				row[k] = ev
+			}
+			continue
+		}
+		row[tag.name] = valueForField(fv)
+	}
+	return row, insertID, nil
+}
+
+func valueForField(v reflect.Value) Value {
+	if v.Kind() == reflect.Struct && v.Type() != timeType && v.Type() != dateType {
+		nested, _, _ := structToValues(v)
+		return nested
+	}
+	return Value(v.Interface())
+}
+
+// structLoader populates the fields of a struct pointer from a row
+// decoded against schema, the reverse of structToValues. It is used by
+// RowIterator.Next when the caller passes a struct pointer instead of a
+// *ValueList or *map[string]Value.
+func structLoader(schema Schema, row []Value, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bigquery: RowIterator.Next: dst must be a pointer to a struct")
+	}
+	sv := v.Elem()
+	byName := fieldsByTagName(sv.Type())
+	for i, fs := range schema {
+		f, ok := byName[fs.Name]
+		if !ok {
+			continue
+		}
+		fv := sv.FieldByIndex(f.Index)
+		if err := setFieldValue(fv, row[i]); err != nil {
+			return fmt.Errorf("bigquery: field %s: %v", fs.Name, err)
+		}
+	}
+	return nil
+}
+
+func fieldsByTagName(t reflect.Type) map[string]reflect.StructField {
+	m := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := parseStructTag(f)
+		if tag.skip || tag.insertID {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name, ef := range fieldsByTagName(f.Type) {
+				ef.Index = append([]int{i}, ef.Index...)
+				m[name] = ef
+			}
+			continue
+		}
+		m[tag.name] = f
+	}
+	return m
+}
+
+func setFieldValue(fv reflect.Value, val Value) error {
+	if val == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Type().AssignableTo(fv.Type()) {
+		fv.Set(rv)
+		return nil
+	}
+	if rv.Type().ConvertibleTo(fv.Type()) {
+		fv.Set(rv.Convert(fv.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot assign %s to %s", rv.Type(), fv.Type())
+}